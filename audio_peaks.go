@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const peakBucketCount = 1000
+
+// progressReader wraps an io.Reader and reports cumulative bytes read to
+// onProgress as the underlying stream is consumed, so long-running decodes
+// can surface progress (e.g. over SSE or a status endpoint) instead of
+// appearing to hang.
+type progressReader struct {
+	reader     io.Reader
+	total      int64
+	read       int64
+	onProgress func(read, total int64)
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.reader.Read(buf)
+	p.read += int64(n)
+	if p.onProgress != nil {
+		p.onProgress(p.read, p.total)
+	}
+	return n, err
+}
+
+// getAudioPeaks decodes the audio track of filePath to mono 8kHz 16-bit PCM
+// via cfg.mediaRuntime and downsamples it to peakBucketCount peaks by taking
+// the max-abs sample in each window, suitable for rendering a waveform
+// overlay.
+func (cfg *apiConfig) getAudioPeaks(ctx context.Context, filePath string, onProgress func(read, total int64)) ([]float32, error) {
+	pcmFile, err := os.CreateTemp("", "tubely-pcm-*.raw")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create PCM temp file: %w", err)
+	}
+	defer os.Remove(pcmFile.Name())
+	pcmFile.Close()
+
+	if err := cfg.mediaRuntime.ExtractAudioPCM(ctx, filePath, pcmFile.Name()); err != nil {
+		return nil, fmt.Errorf("mediaproc PCM extraction error: %w", err)
+	}
+
+	f, err := os.Open(pcmFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open decoded PCM: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't stat decoded PCM: %w", err)
+	}
+
+	pr := &progressReader{reader: f, total: info.Size(), onProgress: onProgress}
+
+	var samples []int16
+	buf := make([]byte, 2)
+	for {
+		_, err := io.ReadFull(pr, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read PCM samples: %w", err)
+		}
+		samples = append(samples, int16(binary.LittleEndian.Uint16(buf)))
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no audio samples decoded")
+	}
+
+	return downsamplePeaks(samples, peakBucketCount), nil
+}
+
+// downsamplePeaks splits samples into numBuckets windows and takes the
+// max-abs amplitude of each, normalized to [0, 1].
+func downsamplePeaks(samples []int16, numBuckets int) []float32 {
+	if numBuckets > len(samples) {
+		numBuckets = len(samples)
+	}
+
+	peaks := make([]float32, numBuckets)
+	windowSize := len(samples) / numBuckets
+
+	for i := 0; i < numBuckets; i++ {
+		start := i * windowSize
+		end := start + windowSize
+		if i == numBuckets-1 {
+			end = len(samples)
+		}
+
+		var maxAbs int16
+		for _, s := range samples[start:end] {
+			if s < 0 {
+				s = -s
+			}
+			if s > maxAbs {
+				maxAbs = s
+			}
+		}
+		peaks[i] = float32(maxAbs) / 32768.0
+	}
+
+	return peaks
+}
+
+// uploadAudioPeaks extracts waveform peaks for filePath, serializes them to
+// peaks.json, and uploads it to S3 next to the video at the given pathID. It
+// returns the peaks file's S3 key; cfg.videoURLSigner turns that into a
+// servable URL the same way it does for the main video.
+func (cfg *apiConfig) uploadAudioPeaks(ctx context.Context, filePath, pathID string) (string, error) {
+	peaks, err := cfg.getAudioPeaks(ctx, filePath, func(read, total int64) {
+		log.Printf("waveform decode progress for %s: %d/%d bytes", pathID, read, total)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(peaks)
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal peaks: %w", err)
+	}
+
+	key := "peaks/" + pathID + ".json"
+	_, err = cfg.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(cfg.s3Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't upload peaks.json: %w", err)
+	}
+
+	return key, nil
+}
@@ -1,10 +1,9 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -12,7 +11,6 @@ import (
 	"mime"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -112,7 +110,7 @@ func (cfg *apiConfig) handlerUploadVideo(w http.ResponseWriter, r *http.Request)
 		prefix = "other/"
 	}
 
-	processedFilePath, err := processVideoForFastStart(tempFile.Name())
+	processedFilePath, err := cfg.processVideoForFastStart(tempFile.Name())
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't process video for fast start", err)
 		return
@@ -145,59 +143,24 @@ func (cfg *apiConfig) handlerUploadVideo(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", cfg.s3Bucket, cfg.s3Region, key)
-	video.VideoURL = &url
-
-	err = cfg.db.UpdateVideo(video)
+	responseVideo, err := cfg.finalizeUploadedVideo(r.Context(), video, processedFilePath, pathID, key)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't update video with URL", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't finish processing video", err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, video)
-}
-
-// FFProbeOutput aspect ratio helper
-type FFProbeOutput struct {
-	Streams []struct {
-		Width  int `json:"width"`
-		Height int `json:"height"`
-	} `json:"streams"`
+	respondWithJSON(w, http.StatusOK, responseVideo)
 }
 
 func (cfg *apiConfig) getVideoAspectRatio(filePath string) (string, error) {
-	log.Println("ffprobe starting with filepath:", filePath)
-
-	command := exec.Command(
-		"ffprobe",
-		"-v", "error", "-show_streams", "-of", "json",
-		filePath)
-
-	var stdout bytes.Buffer
-	command.Stdout = &stdout
-
-	err := command.Run()
-	if err != nil {
-		return "", err
-	}
+	log.Println("probing with mediaproc, filepath:", filePath)
 
-	var ffprobeOutput FFProbeOutput
-
-	err = json.Unmarshal(stdout.Bytes(), &ffprobeOutput)
+	result, err := cfg.mediaRuntime.Probe(context.Background(), filePath)
 	if err != nil {
-		log.Println("json unmarshal error:", err)
 		return "", err
 	}
 
-	if len(ffprobeOutput.Streams) == 0 {
-		log.Println("no streams found in ffprobe output")
-		return "", fmt.Errorf("no streams found in ffprobe output")
-	}
-
-	width := ffprobeOutput.Streams[0].Width
-	height := ffprobeOutput.Streams[0].Height
-
-	ratio := float64(width) / float64(height)
+	ratio := float64(result.Width) / float64(result.Height)
 
 	landscape := 16.0 / 9.0
 	portrait := 9.0 / 16.0
@@ -212,28 +175,23 @@ func (cfg *apiConfig) getVideoAspectRatio(filePath string) (string, error) {
 	}
 }
 
-func processVideoForFastStart(filePath string) (string, error) {
+// getVideoDimensions reads the pixel width and height of the first video
+// stream, for choosing which rungs of the HLS bitrate ladder apply.
+func (cfg *apiConfig) getVideoDimensions(filePath string) (width, height int, err error) {
+	result, err := cfg.mediaRuntime.Probe(context.Background(), filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	return result.Width, result.Height, nil
+}
+
+func (cfg *apiConfig) processVideoForFastStart(filePath string) (string, error) {
 	ext := filepath.Ext(filePath)                // ".mp4"
 	base := strings.TrimSuffix(filePath, ext)    // "/tmp/tubely-upload"
 	outputFilePath := base + ".processing" + ext // "/tmp/tubely-upload.processing.mp4"
-	command := exec.Command(
-		"ffmpeg",
-		"-i",
-		filePath,
-		"-c",
-		"copy",
-		"-movflags",
-		"faststart",
-		"-f",
-		"mp4",
-		outputFilePath)
-
-	var stderr bytes.Buffer
-	command.Stderr = &stderr
-
-	err := command.Run()
-	if err != nil {
-		return "", fmt.Errorf("ffmpeg error: %v, %s", err, stderr.String())
+
+	if err := cfg.mediaRuntime.FastStart(context.Background(), filePath, outputFilePath); err != nil {
+		return "", fmt.Errorf("mediaproc fast start error: %w", err)
 	}
 
 	info, err := os.Stat(outputFilePath)
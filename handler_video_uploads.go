@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/auth"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
+	"github.com/google/uuid"
+)
+
+// videoUploadSession tracks a partially-received chunked upload on disk,
+// keyed by its session UUID. Chunks are appended in order to sessionPath so
+// a flaky connection can retry PATCHes without re-sending bytes already on
+// disk.
+type videoUploadSession struct {
+	ID        uuid.UUID
+	VideoID   uuid.UUID
+	UserID    uuid.UUID
+	Offset    int64
+	TotalSize int64
+}
+
+func sessionPath(uploadDir string, id uuid.UUID) string {
+	return filepath.Join(uploadDir, id.String()+".part")
+}
+
+// handlerCreateVideoUpload handles POST /api/video_uploads, starting a new
+// resumable upload session for a video the caller owns.
+func (cfg *apiConfig) handlerCreateVideoUpload(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		VideoID   uuid.UUID `json:"video_id"`
+		TotalSize int64     `json:"total_size"`
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode request body", err)
+		return
+	}
+	if params.TotalSize <= 0 {
+		respondWithError(w, http.StatusBadRequest, "total_size must be a positive number of bytes", nil)
+		return
+	}
+
+	video, err := cfg.db.GetVideo(params.VideoID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get video", err)
+		return
+	}
+	if video.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "You don't have permission to upload video for this video ID", nil)
+		return
+	}
+
+	sessionID := uuid.New()
+	path := sessionPath(cfg.videoUploadDir, sessionID)
+	f, err := os.Create(path)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create upload session", err)
+		return
+	}
+	f.Close()
+
+	if err := cfg.uploadSessions.save(videoUploadSession{ID: sessionID, VideoID: video.ID, UserID: userID, TotalSize: params.TotalSize}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't persist upload session", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, struct {
+		ID     uuid.UUID `json:"id"`
+		Offset int64     `json:"offset"`
+	}{ID: sessionID, Offset: 0})
+}
+
+// handlerPatchVideoUpload handles PATCH /api/video_uploads/{id}, appending a
+// chunk at the byte offset given by the Upload-Offset header. The caller may
+// mark its last chunk with Upload-Complete: true, but that alone isn't
+// trusted: the chunk is only treated as final once the session's offset
+// actually reaches the total_size declared at POST /api/video_uploads. Once
+// it does, the normal aspect-ratio + fast-start + S3 upload pipeline runs.
+func (cfg *apiConfig) handlerPatchVideoUpload(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid upload ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	session, ok := cfg.uploadSessions.get(sessionID)
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Unknown upload session", nil)
+		return
+	}
+	if session.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "You don't have permission to write to this upload session", nil)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid or missing Upload-Offset header", err)
+		return
+	}
+	if offset != session.Offset {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("Upload-Offset %d doesn't match expected offset %d", offset, session.Offset), nil)
+		return
+	}
+
+	path := sessionPath(cfg.videoUploadDir, sessionID)
+	f, err := os.OpenFile(path, os.O_WRONLY, 0o644)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't open upload session file", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, 0); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't seek upload session file", err)
+		return
+	}
+
+	// Cap the read at exactly the remaining declared size, plus one byte so
+	// an over-long chunk reads as too-large instead of being silently
+	// truncated to a byte count that happens to match.
+	remaining := session.TotalSize - offset
+	written, err := io.Copy(f, io.LimitReader(r.Body, remaining+1))
+	if err != nil {
+		// Keep whatever did reach disk, so the client's retry resumes there.
+		if written <= remaining {
+			session.Offset = offset + written
+			cfg.uploadSessions.update(session)
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't write chunk", err)
+		return
+	}
+	if written > remaining {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("chunk extends past declared total_size %d", session.TotalSize), nil)
+		return
+	}
+
+	session.Offset = offset + written
+	cfg.uploadSessions.update(session)
+
+	if r.Header.Get("Upload-Complete") == "true" && session.Offset != session.TotalSize {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Upload-Complete sent at offset %d, but declared total_size is %d", session.Offset, session.TotalSize), nil)
+		return
+	}
+	if session.Offset < session.TotalSize {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	responseVideo, err := cfg.finishChunkedUpload(r.Context(), session)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't finish upload", err)
+		return
+	}
+	cfg.uploadSessions.delete(sessionID)
+
+	respondWithJSON(w, http.StatusOK, responseVideo)
+}
+
+// handlerHeadVideoUpload handles HEAD /api/video_uploads/{id}, reporting the
+// current byte offset so a client can resume after a dropped connection.
+func (cfg *apiConfig) handlerHeadVideoUpload(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid upload ID", err)
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find JWT", err)
+		return
+	}
+	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate JWT", err)
+		return
+	}
+
+	session, ok := cfg.uploadSessions.get(sessionID)
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Unknown upload session", nil)
+		return
+	}
+	if session.UserID != userID {
+		respondWithError(w, http.StatusUnauthorized, "You don't have permission to read this upload session", nil)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// finishChunkedUpload runs the aspect-ratio + fast-start pipeline against the
+// fully-assembled chunked upload, streams it to S3 via a multipart upload
+// instead of buffering the whole file into PutObject, then hands off to
+// finalizeUploadedVideo to run the same HLS/peaks/thumbnails/signing pipeline
+// handlerUploadVideo does for a single-request upload.
+func (cfg *apiConfig) finishChunkedUpload(ctx context.Context, session videoUploadSession) (database.Video, error) {
+	assembledPath := sessionPath(cfg.videoUploadDir, session.ID)
+	defer os.Remove(assembledPath)
+
+	aspect, err := cfg.getVideoAspectRatio(assembledPath)
+	if err != nil {
+		return database.Video{}, fmt.Errorf("couldn't get video aspect ratio: %w", err)
+	}
+
+	var prefix string
+	switch aspect {
+	case "16:9":
+		prefix = "landscape/"
+	case "9:16":
+		prefix = "portrait/"
+	default:
+		prefix = "other/"
+	}
+
+	processedFilePath, err := cfg.processVideoForFastStart(assembledPath)
+	if err != nil {
+		return database.Video{}, fmt.Errorf("couldn't process video for fast start: %w", err)
+	}
+
+	pathID := session.ID.String()
+	key := prefix + pathID + ".mp4"
+	if err := cfg.multipartUploadFile(ctx, processedFilePath, key, "video/mp4"); err != nil {
+		return database.Video{}, fmt.Errorf("couldn't upload video to S3: %w", err)
+	}
+
+	video, err := cfg.db.GetVideo(session.VideoID)
+	if err != nil {
+		return database.Video{}, fmt.Errorf("couldn't get video: %w", err)
+	}
+
+	return cfg.finalizeUploadedVideo(ctx, video, processedFilePath, pathID, key)
+}
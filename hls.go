@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/media"
+	"github.com/google/uuid"
+)
+
+// videoTranscodeUpdater adapts cfg.db to the media.StatusUpdater interface
+// the transcode queue uses to report progress back to the video record. Both
+// methods below go through cfg.db's single-column setters rather than a
+// GetVideo-then-UpdateVideo round trip: this worker runs concurrently with
+// the upload handler's own writes (video_pipeline.go's SetVideoPeaksURL/
+// SetVideoThumbnailUploaded calls) against the same row, and a full-row
+// UpdateVideo from either side would silently clobber whatever the other
+// just wrote.
+type videoTranscodeUpdater struct {
+	cfg *apiConfig
+}
+
+func (u videoTranscodeUpdater) SetTranscodeStatus(videoID uuid.UUID, status media.Status) error {
+	return u.cfg.db.SetTranscodeStatus(videoID, string(status))
+}
+
+func (u videoTranscodeUpdater) SetMasterPlaylistKey(videoID uuid.UUID, key string) error {
+	return u.cfg.db.SetMasterPlaylistKey(videoID, key)
+}
+
+// newHLSQueue builds the background transcode queue used by
+// handlerUploadVideo. It runs ffmpeg via cfg.mediaRuntime/media.BuildHLS and
+// uploads the resulting tree to S3 under job.DestPathPrefix, returning the
+// master playlist's S3 key (not a URL) so it can be signed fresh on every
+// response.
+func newHLSQueue(cfg *apiConfig, numWorkers int) *media.TranscodeQueue {
+	return media.NewTranscodeQueue(numWorkers, videoTranscodeUpdater{cfg: cfg}, func(ctx context.Context, job media.TranscodeJob) (string, error) {
+		// outputDir is created as a sibling of the source file, not an
+		// unrelated temp dir, so the two can be mounted into the wasm guest
+		// as a single directory tree.
+		hostDir := filepath.Dir(job.SourcePath)
+		outputDir, err := os.MkdirTemp(hostDir, "tubely-hls")
+		if err != nil {
+			return "", fmt.Errorf("couldn't create HLS output dir: %w", err)
+		}
+		defer os.RemoveAll(outputDir)
+
+		renditions := media.RenditionsForSource(job.SourceWidth, job.SourceHeight)
+		masterPath, err := media.BuildHLS(ctx, cfg.mediaRuntime, hostDir, job.SourcePath, outputDir, renditions, job.SourceWidth, job.SourceHeight)
+		if err != nil {
+			return "", err
+		}
+
+		if err := uploadHLSTree(ctx, cfg, outputDir, job.DestPathPrefix); err != nil {
+			return "", err
+		}
+
+		return job.DestPathPrefix + filepath.Base(masterPath), nil
+	})
+}
+
+// uploadHLSTree walks the local HLS output directory and uploads every
+// playlist and segment to S3 under keyPrefix, preserving the per-rendition
+// directory structure.
+func uploadHLSTree(ctx context.Context, cfg *apiConfig, localDir, keyPrefix string) error {
+	return filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		key := keyPrefix + filepath.ToSlash(rel)
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		contentType := "application/vnd.apple.mpegurl"
+		if filepath.Ext(path) == ".ts" {
+			contentType = "video/mp2t"
+		}
+
+		_, err = cfg.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(cfg.s3Bucket),
+			Key:         aws.String(key),
+			Body:        f,
+			ContentType: aws.String(contentType),
+		})
+		return err
+	})
+}
@@ -0,0 +1,103 @@
+package media
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transcodeTimeout bounds a single job's run func, so a wedged ffmpeg
+// invocation gets its context canceled (and the wasm guest module torn down,
+// see mediaproc.runModule) instead of running the worker forever.
+const transcodeTimeout = 30 * time.Minute
+
+// TranscodeJob describes one video awaiting HLS transcoding.
+type TranscodeJob struct {
+	VideoID        uuid.UUID
+	SourcePath     string
+	SourceWidth    int
+	SourceHeight   int
+	DestPathPrefix string // e.g. "hls/<pathID>/"
+}
+
+// StatusUpdater persists a video's transcode status and, once ready, its
+// master playlist's S3 key (not a URL — the app signs a fresh URL from the
+// key on every response, the same way it does for the main video).
+// Implementations typically wrap the app's DB.
+type StatusUpdater interface {
+	SetTranscodeStatus(videoID uuid.UUID, status Status) error
+	SetMasterPlaylistKey(videoID uuid.UUID, key string) error
+}
+
+// TranscodeQueue runs HLS transcode jobs on a fixed pool of background
+// workers so an upload request can return as soon as the source file is
+// durably stored, without waiting on ffmpeg.
+type TranscodeQueue struct {
+	jobs    chan TranscodeJob
+	updater StatusUpdater
+	run     func(context.Context, TranscodeJob) (masterKey string, err error)
+
+	wg sync.WaitGroup
+}
+
+// NewTranscodeQueue starts numWorkers background goroutines pulling from an
+// internal job channel. run performs the actual transcode + upload and
+// returns the master playlist's S3 key to persist.
+func NewTranscodeQueue(numWorkers int, updater StatusUpdater, run func(context.Context, TranscodeJob) (string, error)) *TranscodeQueue {
+	q := &TranscodeQueue{
+		jobs:    make(chan TranscodeJob, 64),
+		updater: updater,
+		run:     run,
+	}
+	for i := 0; i < numWorkers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+	return q
+}
+
+func (q *TranscodeQueue) worker() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		if err := q.updater.SetTranscodeStatus(job.VideoID, StatusProcessing); err != nil {
+			log.Printf("media: couldn't mark video %s processing: %v", job.VideoID, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), transcodeTimeout)
+		masterKey, err := q.run(ctx, job)
+		cancel()
+		if err != nil {
+			log.Printf("media: transcode failed for video %s: %v", job.VideoID, err)
+			if uerr := q.updater.SetTranscodeStatus(job.VideoID, StatusFailed); uerr != nil {
+				log.Printf("media: couldn't mark video %s failed: %v", job.VideoID, uerr)
+			}
+			continue
+		}
+
+		if err := q.updater.SetMasterPlaylistKey(job.VideoID, masterKey); err != nil {
+			log.Printf("media: couldn't persist playlist key for video %s: %v", job.VideoID, err)
+			continue
+		}
+		if err := q.updater.SetTranscodeStatus(job.VideoID, StatusReady); err != nil {
+			log.Printf("media: couldn't mark video %s ready: %v", job.VideoID, err)
+		}
+	}
+}
+
+// Enqueue submits a job for asynchronous processing. It does not block on
+// the transcode itself, only on the (buffered) channel send.
+func (q *TranscodeQueue) Enqueue(job TranscodeJob) {
+	if err := q.updater.SetTranscodeStatus(job.VideoID, StatusPending); err != nil {
+		log.Printf("media: couldn't mark video %s pending: %v", job.VideoID, err)
+	}
+	q.jobs <- job
+}
+
+// Close stops accepting new jobs and waits for in-flight workers to drain.
+func (q *TranscodeQueue) Close() {
+	close(q.jobs)
+	q.wg.Wait()
+}
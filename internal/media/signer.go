@@ -0,0 +1,30 @@
+package media
+
+import (
+	"fmt"
+	"time"
+)
+
+// VideoURLSigner turns a stored S3 object key into a URL a client can fetch.
+// The default implementation returns a plain public S3 URL; a CloudFront
+// implementation instead returns a time-limited signed URL so buckets can be
+// kept private.
+type VideoURLSigner interface {
+	SignURL(key string) (string, error)
+}
+
+// DefaultTTL is used by signer implementations when no explicit TTL is
+// configured.
+const DefaultTTL = time.Hour
+
+// S3Signer is the default VideoURLSigner, returning the plain public S3
+// object URL. Used when no CloudFront distribution is configured.
+type S3Signer struct {
+	Bucket string
+	Region string
+}
+
+// SignURL returns the public https://<bucket>.s3.<region>.amazonaws.com/<key> URL.
+func (s S3Signer) SignURL(key string) (string, error) {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, key), nil
+}
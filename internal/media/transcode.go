@@ -0,0 +1,135 @@
+// Package media builds adaptive-bitrate HLS ladders from a source video and
+// tracks their transcode status so uploads don't block on ffmpeg.
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/mediaproc"
+)
+
+// Status is the lifecycle of an asynchronous transcode job.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusReady      Status = "ready"
+	StatusFailed     Status = "failed"
+)
+
+// Rendition is one rung of the HLS bitrate ladder. ShortEdge is the rung's
+// conventional resolution (1080/720/480/240) applied to whichever source
+// dimension is actually the short edge, so portrait sources scale by width
+// instead of being squashed into landscape dimensions.
+type Rendition struct {
+	Name      string
+	ShortEdge int
+	Bitrate   int // target video bitrate in bits/sec
+}
+
+// ladder is ordered from highest to lowest quality.
+var ladder = []Rendition{
+	{Name: "1080p", ShortEdge: 1080, Bitrate: 5_000_000},
+	{Name: "720p", ShortEdge: 720, Bitrate: 2_800_000},
+	{Name: "480p", ShortEdge: 480, Bitrate: 1_400_000},
+	{Name: "240p", ShortEdge: 240, Bitrate: 600_000},
+}
+
+// RenditionsForSource returns the ladder rungs whose short edge is at or
+// below the source's own short edge (the source's height for a landscape
+// video, its width for a portrait one), always keeping at least the lowest
+// rung so small source videos still get a playable HLS stream.
+func RenditionsForSource(sourceWidth, sourceHeight int) []Rendition {
+	shortEdge := sourceHeight
+	if sourceWidth < sourceHeight {
+		shortEdge = sourceWidth
+	}
+
+	var out []Rendition
+	for _, r := range ladder {
+		if r.ShortEdge <= shortEdge {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, ladder[len(ladder)-1])
+	}
+	return out
+}
+
+// scaleFilter returns the ffmpeg -vf value that scales to rendition's short
+// edge while letting ffmpeg compute the other (even) dimension, so aspect
+// ratio is preserved regardless of source orientation.
+func scaleFilter(r Rendition, sourceWidth, sourceHeight int) string {
+	if sourceWidth < sourceHeight {
+		// Portrait: width is the short edge.
+		return fmt.Sprintf("scale=%d:-2", r.ShortEdge)
+	}
+	// Landscape or square: height is the short edge.
+	return fmt.Sprintf("scale=-2:%d", r.ShortEdge)
+}
+
+// resolutionFor computes the rendition's actual output resolution for the
+// master playlist's RESOLUTION attribute, mirroring the rounding ffmpeg's
+// -2 scale filter applies (round to the nearest even pixel).
+func resolutionFor(r Rendition, sourceWidth, sourceHeight int) (width, height int) {
+	if sourceWidth < sourceHeight {
+		width = r.ShortEdge
+		height = int(math.Round(float64(r.ShortEdge)*float64(sourceHeight)/float64(sourceWidth)/2)) * 2
+	} else {
+		height = r.ShortEdge
+		width = int(math.Round(float64(r.ShortEdge)*float64(sourceWidth)/float64(sourceHeight)/2)) * 2
+	}
+	return width &^ 1, height &^ 1
+}
+
+// BuildHLS transcodes inputPath into the given renditions via rt, writing
+// each rendition's playlist and segments under outputDir/<rendition.Name>/
+// and a master playlist at outputDir/master.m3u8. hostDir is the common
+// ancestor of inputPath and outputDir to mount into the wasm guest — same
+// constraint rt's other methods have, just spanning both directories here
+// instead of one. sourceWidth/sourceHeight are the source video's
+// dimensions, used to scale by the correct edge and to report each
+// rendition's resolution in the master playlist. It returns the path to the
+// master playlist.
+func BuildHLS(ctx context.Context, rt *mediaproc.Runtime, hostDir, inputPath, outputDir string, renditions []Rendition, sourceWidth, sourceHeight int) (string, error) {
+	if len(renditions) == 0 {
+		return "", fmt.Errorf("media: no renditions to transcode")
+	}
+
+	var master bytes.Buffer
+	master.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+
+	for _, r := range renditions {
+		renditionDir := filepath.Join(outputDir, r.Name)
+		if err := os.MkdirAll(renditionDir, 0o755); err != nil {
+			return "", fmt.Errorf("media: couldn't create rendition dir: %w", err)
+		}
+
+		playlistPath := filepath.Join(renditionDir, "index.m3u8")
+		segmentPattern := filepath.Join(renditionDir, "segment%03d.ts")
+
+		if err := rt.TranscodeHLSRendition(ctx, hostDir, inputPath, playlistPath, segmentPattern, scaleFilter(r, sourceWidth, sourceHeight), r.Bitrate); err != nil {
+			return "", fmt.Errorf("media: ffmpeg error transcoding %s: %w", r.Name, err)
+		}
+
+		width, height := resolutionFor(r, sourceWidth, sourceHeight)
+		master.WriteString(fmt.Sprintf(
+			"#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n%s/index.m3u8\n",
+			r.Bitrate, width, height, r.Name,
+		))
+	}
+
+	masterPath := filepath.Join(outputDir, "master.m3u8")
+	if err := os.WriteFile(masterPath, master.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("media: couldn't write master playlist: %w", err)
+	}
+
+	return masterPath, nil
+}
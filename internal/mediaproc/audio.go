@@ -0,0 +1,39 @@
+package mediaproc
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// ExtractAudioPCM runs ffmpeg.wasm to decode the audio track of in to raw,
+// mono, 8kHz 16-bit PCM, writing the result to out. in and out must live in
+// the same directory, same constraint as FastStart.
+func (rt *Runtime) ExtractAudioPCM(ctx context.Context, in, out string) error {
+	hostDir := filepath.Dir(in)
+	if filepath.Dir(out) != hostDir {
+		return fmt.Errorf("mediaproc: in and out must share a directory, got %q and %q", in, out)
+	}
+
+	inGuest, err := guestPath(hostDir, in)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map input: %w", err)
+	}
+	outGuest, err := guestPath(hostDir, out)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map output: %w", err)
+	}
+
+	args := []string{
+		"ffmpeg",
+		"-i", inGuest,
+		"-vn",
+		"-f", "s16le",
+		"-ac", "1",
+		"-ar", "8000",
+		outGuest,
+	}
+
+	_, _, err = runModule(ctx, rt.runtime, rt.ffmpeg, hostDir, args)
+	return err
+}
@@ -0,0 +1,47 @@
+package mediaproc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// runModule instantiates a fresh copy of compiled from its shared
+// compilation cache, maps hostDir into the guest at "/work" (read-write so
+// ffmpeg can write its output alongside the input), and runs it with args.
+// It honors ctx cancellation: closing the module stops execution.
+func runModule(ctx context.Context, runtime wazero.Runtime, compiled wazero.CompiledModule, hostDir string, args []string) (stdout, stderr []byte, err error) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+
+	fsConfig := wazero.NewFSConfig().WithDirMount(hostDir, "/work")
+
+	config := wazero.NewModuleConfig().
+		WithArgs(args...).
+		WithFSConfig(fsConfig).
+		WithStdout(&stdoutBuf).
+		WithStderr(&stderrBuf).
+		WithStartFunctions("_start")
+
+	mod, err := runtime.InstantiateModule(ctx, compiled, config)
+	if mod != nil {
+		defer mod.Close(ctx)
+	}
+	if err != nil {
+		return stdoutBuf.Bytes(), stderrBuf.Bytes(), fmt.Errorf("mediaproc: guest execution failed: %w, stderr: %s", err, stderrBuf.String())
+	}
+
+	return stdoutBuf.Bytes(), stderrBuf.Bytes(), nil
+}
+
+// guestPath rewrites a host path under hostDir to its "/work/..." mapping
+// inside the guest filesystem.
+func guestPath(hostDir, hostPath string) (string, error) {
+	rel, err := filepath.Rel(hostDir, hostPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(filepath.Join("/work", rel)), nil
+}
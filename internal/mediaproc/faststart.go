@@ -0,0 +1,39 @@
+package mediaproc
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// FastStart runs ffmpeg.wasm to remux the video at in into out with the moov
+// atom moved to the front of the file, so playback can begin before the
+// whole file downloads. in and out must live in the same directory; ffmpeg's
+// wasm build only sees the single host directory mapped into its sandbox.
+func (rt *Runtime) FastStart(ctx context.Context, in, out string) error {
+	hostDir := filepath.Dir(in)
+	if filepath.Dir(out) != hostDir {
+		return fmt.Errorf("mediaproc: in and out must share a directory, got %q and %q", in, out)
+	}
+
+	inGuest, err := guestPath(hostDir, in)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map input: %w", err)
+	}
+	outGuest, err := guestPath(hostDir, out)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map output: %w", err)
+	}
+
+	args := []string{
+		"ffmpeg",
+		"-i", inGuest,
+		"-c", "copy",
+		"-movflags", "faststart",
+		"-f", "mp4",
+		outGuest,
+	}
+
+	_, _, err = runModule(ctx, rt.runtime, rt.ffmpeg, hostDir, args)
+	return err
+}
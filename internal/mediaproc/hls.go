@@ -0,0 +1,44 @@
+package mediaproc
+
+import (
+	"context"
+	"fmt"
+)
+
+// TranscodeHLSRendition runs ffmpeg.wasm to transcode in into one HLS
+// rendition, writing its playlist to playlistPath and its segments to
+// segmentPattern (an ffmpeg strftime/%d pattern). Unlike FastStart/
+// GeneratePoster, in, playlistPath, and segmentPattern don't need to share a
+// directory directly — the caller passes hostDir, the common ancestor
+// directory to mount into the guest, since an HLS rendition's output
+// naturally lives in its own subdirectory of the job's scratch space.
+func (rt *Runtime) TranscodeHLSRendition(ctx context.Context, hostDir, in, playlistPath, segmentPattern, scaleFilter string, bitrate int) error {
+	inGuest, err := guestPath(hostDir, in)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map input: %w", err)
+	}
+	playlistGuest, err := guestPath(hostDir, playlistPath)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map playlist path: %w", err)
+	}
+	segmentGuest, err := guestPath(hostDir, segmentPattern)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map segment pattern: %w", err)
+	}
+
+	args := []string{
+		"ffmpeg", "-y",
+		"-i", inGuest,
+		"-vf", scaleFilter,
+		"-b:v", fmt.Sprintf("%d", bitrate),
+		"-c:a", "aac",
+		"-f", "hls",
+		"-hls_time", "6",
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", segmentGuest,
+		playlistGuest,
+	}
+
+	_, _, err = runModule(ctx, rt.runtime, rt.ffmpeg, hostDir, args)
+	return err
+}
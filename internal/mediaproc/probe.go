@@ -0,0 +1,83 @@
+package mediaproc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// ProbeResult is the subset of ffprobe's output tubely needs: the first
+// video stream's dimensions, the container duration, and any rotation
+// side-data set by phones recording in portrait orientation.
+type ProbeResult struct {
+	Width           int
+	Height          int
+	DurationSeconds float64
+	Rotation        int
+}
+
+type ffprobeJSON struct {
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+	Streams []struct {
+		Width        int `json:"width"`
+		Height       int `json:"height"`
+		SideDataList []struct {
+			Rotation int `json:"rotation"`
+		} `json:"side_data_list"`
+	} `json:"streams"`
+}
+
+// Probe runs ffprobe.wasm against the file at path and returns its
+// dimensions, duration, and rotation.
+func (rt *Runtime) Probe(ctx context.Context, path string) (*ProbeResult, error) {
+	hostDir := filepath.Dir(path)
+	inGuest, err := guestPath(hostDir, path)
+	if err != nil {
+		return nil, fmt.Errorf("mediaproc: couldn't map probe input: %w", err)
+	}
+
+	args := []string{
+		"ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "format=duration",
+		"-show_entries", "stream=width,height,side_data_list",
+		"-of", "json",
+		inGuest,
+	}
+
+	stdout, _, err := runModule(ctx, rt.runtime, rt.ffprobe, hostDir, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed ffprobeJSON
+	if err := json.Unmarshal(stdout, &parsed); err != nil {
+		return nil, fmt.Errorf("mediaproc: couldn't parse ffprobe output: %w", err)
+	}
+	if len(parsed.Streams) == 0 {
+		return nil, fmt.Errorf("mediaproc: no streams found in ffprobe output")
+	}
+
+	result := &ProbeResult{
+		Width:  parsed.Streams[0].Width,
+		Height: parsed.Streams[0].Height,
+	}
+	if d, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil {
+		result.DurationSeconds = d
+	}
+
+	for _, stream := range parsed.Streams {
+		for _, sd := range stream.SideDataList {
+			if sd.Rotation != 0 {
+				result.Rotation = sd.Rotation
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,70 @@
+// Package mediaproc runs ffmpeg and ffprobe in-process as WebAssembly
+// modules via wazero, so tubely no longer shells out to host binaries on
+// $PATH and can bound each call's CPU/memory and cancel it via context.
+package mediaproc
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// maxMemoryPages bounds each guest module instance's linear memory; wazero
+// pages are 64KiB, so this is a 256MiB ceiling.
+const maxMemoryPages = 4096
+
+// Runtime owns a single wazero runtime, a shared compilation cache, and the
+// precompiled ffmpeg/ffprobe modules. Create one at server startup with
+// NewRuntime and reuse it for every Probe/FastStart call; compiling a wasm
+// module is too expensive to redo per request.
+type Runtime struct {
+	runtime wazero.Runtime
+	ffmpeg  wazero.CompiledModule
+	ffprobe wazero.CompiledModule
+}
+
+// NewRuntime compiles the ffmpeg.wasm and ffprobe.wasm modules found at the
+// given paths and instantiates WASI support shared across every call.
+func NewRuntime(ctx context.Context, ffmpegWasmPath, ffprobeWasmPath string) (*Runtime, error) {
+	cache := wazero.NewCompilationCache()
+	config := wazero.NewRuntimeConfig().
+		WithCompilationCache(cache).
+		WithCloseOnContextDone(true).        // canceling a call's ctx stops its guest instead of letting it run on
+		WithMemoryLimitPages(maxMemoryPages) // 256MiB ceiling per guest instance, so a bad input can't balloon memory
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("mediaproc: couldn't instantiate WASI: %w", err)
+	}
+
+	ffmpeg, err := compileModule(ctx, runtime, ffmpegWasmPath)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("mediaproc: couldn't compile ffmpeg.wasm: %w", err)
+	}
+
+	ffprobe, err := compileModule(ctx, runtime, ffprobeWasmPath)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("mediaproc: couldn't compile ffprobe.wasm: %w", err)
+	}
+
+	return &Runtime{runtime: runtime, ffmpeg: ffmpeg, ffprobe: ffprobe}, nil
+}
+
+func compileModule(ctx context.Context, runtime wazero.Runtime, path string) (wazero.CompiledModule, error) {
+	wasmBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.CompileModule(ctx, wasmBytes)
+}
+
+// Close releases the wazero runtime and its compiled modules.
+func (rt *Runtime) Close(ctx context.Context) error {
+	return rt.runtime.Close(ctx)
+}
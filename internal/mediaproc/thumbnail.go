@@ -0,0 +1,70 @@
+package mediaproc
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// GeneratePoster runs ffmpeg.wasm to grab a single JPEG poster frame from in
+// at timestampSeconds, applying rotateFilter (an ffmpeg -vf prefix such as
+// "transpose=1,", or "" for none) to undo any
+// portrait-phone rotation tag before scaling to 640px wide. in and out must
+// share a directory, same constraint as FastStart.
+func (rt *Runtime) GeneratePoster(ctx context.Context, in, out string, timestampSeconds float64, rotateFilter string) error {
+	return rt.runFFmpegPair(ctx, in, out, []string{
+		"-ss", fmt.Sprintf("%.3f", timestampSeconds),
+		"-i", "{in}",
+		"-vframes", "1",
+		"-vf", fmt.Sprintf("%sthumbnail,scale=640:-1", rotateFilter),
+		"{out}",
+	})
+}
+
+// GeneratePreview runs ffmpeg.wasm to build a short animated WebP preview
+// from in by sampling every 30th frame, applying rotateFilter the same way
+// GeneratePoster does. in and out must share a directory.
+func (rt *Runtime) GeneratePreview(ctx context.Context, in, out string, rotateFilter string) error {
+	return rt.runFFmpegPair(ctx, in, out, []string{
+		"-i", "{in}",
+		"-vf", fmt.Sprintf("%sselect='not(mod(n,30))',scale=480:-1", rotateFilter),
+		"-vsync", "vfr",
+		"-loop", "0",
+		"{out}",
+	})
+}
+
+// runFFmpegPair maps in/out into the guest filesystem and runs ffmpeg.wasm
+// with argTemplate, substituting "{in}"/"{out}" placeholders with their
+// guest-mapped paths.
+func (rt *Runtime) runFFmpegPair(ctx context.Context, in, out string, argTemplate []string) error {
+	hostDir := filepath.Dir(in)
+	if filepath.Dir(out) != hostDir {
+		return fmt.Errorf("mediaproc: in and out must share a directory, got %q and %q", in, out)
+	}
+
+	inGuest, err := guestPath(hostDir, in)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map input: %w", err)
+	}
+	outGuest, err := guestPath(hostDir, out)
+	if err != nil {
+		return fmt.Errorf("mediaproc: couldn't map output: %w", err)
+	}
+
+	args := make([]string, 0, len(argTemplate)+1)
+	args = append(args, "ffmpeg", "-y")
+	for _, a := range argTemplate {
+		switch a {
+		case "{in}":
+			args = append(args, inGuest)
+		case "{out}":
+			args = append(args, outGuest)
+		default:
+			args = append(args, a)
+		}
+	}
+
+	_, _, err = runModule(ctx, rt.runtime, rt.ffmpeg, hostDir, args)
+	return err
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/mediaproc"
+)
+
+// newMediaRuntime compiles the precompiled ffmpeg/ffprobe wasm modules once
+// at startup and returns the shared runtime cfg.mediaRuntime uses for every
+// probe and fast-start call, so requests never shell out to host binaries.
+//
+// Recognized env vars:
+//
+//	FFMPEG_WASM_PATH  - path to ffmpeg.wasm (default "./wasm/ffmpeg.wasm")
+//	FFPROBE_WASM_PATH - path to ffprobe.wasm (default "./wasm/ffprobe.wasm")
+func newMediaRuntime(ctx context.Context) (*mediaproc.Runtime, error) {
+	ffmpegPath := os.Getenv("FFMPEG_WASM_PATH")
+	if ffmpegPath == "" {
+		ffmpegPath = "./wasm/ffmpeg.wasm"
+	}
+	ffprobePath := os.Getenv("FFPROBE_WASM_PATH")
+	if ffprobePath == "" {
+		ffprobePath = "./wasm/ffprobe.wasm"
+	}
+
+	return mediaproc.NewRuntime(ctx, ffmpegPath, ffprobePath)
+}
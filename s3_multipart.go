@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// multipartChunkSize is the size of each part sent to S3. S3 requires every
+// part but the last to be at least 5MiB.
+const multipartChunkSize = 8 << 20 // 8MiB
+
+// multipartUploadFile streams localPath to S3 in fixed-size parts via
+// CreateMultipartUpload/UploadPart/CompleteMultipartUpload, so a processed
+// 5GB+ video never has to be buffered whole into a single PutObject call.
+func (cfg *apiConfig) multipartUploadFile(ctx context.Context, localPath, key, contentType string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	created, err := cfg.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(cfg.s3Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create multipart upload: %w", err)
+	}
+	uploadID := created.UploadId
+
+	var parts []types.CompletedPart
+	buf := make([]byte, multipartChunkSize)
+	partNumber := int32(1)
+
+	for {
+		n, readErr := io.ReadFull(f, buf)
+		if n > 0 {
+			uploaded, uploadErr := cfg.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:     aws.String(cfg.s3Bucket),
+				Key:        aws.String(key),
+				UploadId:   uploadID,
+				PartNumber: aws.Int32(partNumber),
+				Body:       bytes.NewReader(buf[:n]),
+			})
+			if uploadErr != nil {
+				cfg.abortMultipartUpload(ctx, key, uploadID)
+				return fmt.Errorf("couldn't upload part %d: %w", partNumber, uploadErr)
+			}
+			parts = append(parts, types.CompletedPart{
+				ETag:       uploaded.ETag,
+				PartNumber: aws.Int32(partNumber),
+			})
+			partNumber++
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			cfg.abortMultipartUpload(ctx, key, uploadID)
+			return fmt.Errorf("couldn't read %q: %w", localPath, readErr)
+		}
+	}
+
+	_, err = cfg.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(cfg.s3Bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: parts,
+		},
+	})
+	if err != nil {
+		cfg.abortMultipartUpload(ctx, key, uploadID)
+		return fmt.Errorf("couldn't complete multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) abortMultipartUpload(ctx context.Context, key string, uploadID *string) {
+	_, err := cfg.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(cfg.s3Bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	if err != nil {
+		log.Printf("couldn't abort multipart upload for key %q: %v", key, err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/media"
+)
+
+// newVideoURLSigner builds the apiConfig's VideoURLSigner. When
+// CLOUDFRONT_DOMAIN is set, videos are served through a CloudFront
+// distribution using signed URLs; otherwise tubely falls back to plain,
+// public S3 URLs.
+//
+// Recognized env vars:
+//
+//	CLOUDFRONT_DOMAIN             - distribution domain, e.g. d111111abcdef8.cloudfront.net
+//	CLOUDFRONT_KEY_PAIR_ID        - CloudFront public key ID
+//	CLOUDFRONT_PRIVATE_KEY_PATH   - path to the matching PEM private key
+//	CLOUDFRONT_URL_TTL            - signed URL lifetime, e.g. "1h" (default 1h)
+func newVideoURLSigner(s3Bucket, s3Region string) (media.VideoURLSigner, error) {
+	domain := os.Getenv("CLOUDFRONT_DOMAIN")
+	if domain == "" {
+		return media.S3Signer{Bucket: s3Bucket, Region: s3Region}, nil
+	}
+
+	ttl := media.DefaultTTL
+	if raw := os.Getenv("CLOUDFRONT_URL_TTL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err == nil {
+			ttl = parsed
+		}
+	}
+
+	return media.NewCloudFrontSigner(
+		domain,
+		os.Getenv("CLOUDFRONT_KEY_PAIR_ID"),
+		os.Getenv("CLOUDFRONT_PRIVATE_KEY_PATH"),
+		ttl,
+	)
+}
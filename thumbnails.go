@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// posterTimestamp picks min(3s, duration/2) so short clips still get a
+// poster frame instead of seeking past the end of the file.
+func posterTimestamp(duration float64) float64 {
+	return math.Min(3.0, duration/2)
+}
+
+// rotationFilter returns the ffmpeg vf fragment needed to undo a stream's
+// rotate side-data tag, or "" if the video is already upright.
+func rotationFilter(rotation int) string {
+	switch ((rotation % 360) + 360) % 360 {
+	case 90:
+		return "transpose=1,"
+	case 180:
+		return "transpose=1,transpose=1,"
+	case 270:
+		return "transpose=2,"
+	default:
+		return ""
+	}
+}
+
+// generateThumbnails produces a JPEG poster frame and a short animated WebP
+// preview from filePath, writing them to posterPath and previewPath, via
+// cfg.mediaRuntime rather than host ffmpeg/ffprobe binaries.
+func (cfg *apiConfig) generateThumbnails(ctx context.Context, filePath, posterPath, previewPath string) error {
+	result, err := cfg.mediaRuntime.Probe(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	rotate := rotationFilter(result.Rotation)
+	ts := posterTimestamp(result.DurationSeconds)
+
+	if err := cfg.mediaRuntime.GeneratePoster(ctx, filePath, posterPath, ts, rotate); err != nil {
+		return fmt.Errorf("mediaproc poster error: %w", err)
+	}
+	if err := cfg.mediaRuntime.GeneratePreview(ctx, filePath, previewPath, rotate); err != nil {
+		return fmt.Errorf("mediaproc preview error: %w", err)
+	}
+
+	return nil
+}
+
+// uploadThumbnails generates a poster JPEG and animated WebP preview for
+// processedFilePath, uploads both to S3, and returns their S3 keys;
+// cfg.videoURLSigner turns those into servable URLs the same way it does for
+// the main video.
+func (cfg *apiConfig) uploadThumbnails(ctx context.Context, processedFilePath, pathID string) (posterKey, previewKey string, err error) {
+	posterPath, err := os.CreateTemp("", "tubely-poster-*.jpg")
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't create poster temp file: %w", err)
+	}
+	defer os.Remove(posterPath.Name())
+	posterPath.Close()
+
+	previewPath, err := os.CreateTemp("", "tubely-preview-*.webp")
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't create preview temp file: %w", err)
+	}
+	defer os.Remove(previewPath.Name())
+	previewPath.Close()
+
+	if err := cfg.generateThumbnails(ctx, processedFilePath, posterPath.Name(), previewPath.Name()); err != nil {
+		return "", "", err
+	}
+
+	posterKey = "thumbnails/" + pathID + ".jpg"
+	if err := cfg.putS3File(ctx, posterPath.Name(), posterKey, "image/jpeg"); err != nil {
+		return "", "", fmt.Errorf("couldn't upload poster: %w", err)
+	}
+
+	previewKey = "previews/" + pathID + ".webp"
+	if err := cfg.putS3File(ctx, previewPath.Name(), previewKey, "image/webp"); err != nil {
+		return "", "", fmt.Errorf("couldn't upload preview: %w", err)
+	}
+
+	return posterKey, previewKey, nil
+}
+
+// putS3File uploads the file at localPath to the given S3 key.
+func (cfg *apiConfig) putS3File(ctx context.Context, localPath, key, contentType string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = cfg.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(cfg.s3Bucket),
+		Key:         aws.String(key),
+		Body:        f,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/media"
+)
+
+// finalizeUploadedVideo runs the post-upload pipeline shared by the
+// single-request (handler_upload_video.go) and chunked (handler_video_uploads.go)
+// upload paths once a video's raw bytes are already sitting at key in S3: it
+// persists the key and marks the video pending transcode, kicks off async
+// HLS transcoding, generates waveform peaks and thumbnails, and signs every
+// asset URL for the response.
+func (cfg *apiConfig) finalizeUploadedVideo(ctx context.Context, video database.Video, processedFilePath, pathID, key string) (database.Video, error) {
+	video.VideoURL = &key
+	video.TranscodeStatus = string(media.StatusPending)
+
+	if err := cfg.db.UpdateVideo(video); err != nil {
+		return database.Video{}, fmt.Errorf("couldn't update video with URL: %w", err)
+	}
+
+	if width, height, err := cfg.getVideoDimensions(processedFilePath); err != nil {
+		log.Printf("Couldn't read video dimensions, skipping HLS transcode: %v", err)
+	} else {
+		cfg.hlsQueue.Enqueue(media.TranscodeJob{
+			VideoID:        video.ID,
+			SourcePath:     processedFilePath,
+			SourceWidth:    width,
+			SourceHeight:   height,
+			DestPathPrefix: "hls/" + pathID + "/",
+		})
+	}
+
+	// From here on, cfg.hlsQueue's worker is concurrently writing this same
+	// row's TranscodeStatus/MasterPlaylistURL (internal/media/queue.go), so
+	// every remaining write uses a field-scoped setter instead of
+	// UpdateVideo(video) — writing back our stale in-memory copy would
+	// clobber whatever the worker has written since we fetched it.
+	responseVideo := video
+
+	if peaksKey, err := cfg.uploadAudioPeaks(ctx, processedFilePath, pathID); err != nil {
+		log.Printf("Couldn't generate waveform peaks, skipping: %v", err)
+	} else if err := cfg.db.SetVideoPeaksURL(video.ID, peaksKey); err != nil {
+		log.Printf("Couldn't save peaks key for video %s: %v", video.ID, err)
+	} else if signed, err := cfg.videoURLSigner.SignURL(peaksKey); err != nil {
+		log.Printf("Couldn't sign peaks URL for video %s: %v", video.ID, err)
+	} else {
+		responseVideo.PeaksURL = &signed
+	}
+
+	if posterKey, previewKey, err := cfg.uploadThumbnails(ctx, processedFilePath, pathID); err != nil {
+		log.Printf("Couldn't generate thumbnails, skipping: %v", err)
+	} else if err := cfg.db.SetVideoThumbnailUploaded(video.ID, posterKey, previewKey); err != nil {
+		log.Printf("Couldn't save thumbnail keys for video %s: %v", video.ID, err)
+	} else {
+		if signed, err := cfg.videoURLSigner.SignURL(posterKey); err != nil {
+			log.Printf("Couldn't sign poster URL for video %s: %v", video.ID, err)
+		} else {
+			responseVideo.ThumbnailURL = &signed
+		}
+		if signed, err := cfg.videoURLSigner.SignURL(previewKey); err != nil {
+			log.Printf("Couldn't sign preview URL for video %s: %v", video.ID, err)
+		} else {
+			responseVideo.PreviewURL = &signed
+		}
+	}
+
+	signedURL, err := cfg.videoURLSigner.SignURL(key)
+	if err != nil {
+		return database.Video{}, fmt.Errorf("couldn't sign video URL: %w", err)
+	}
+	responseVideo.VideoURL = &signedURL
+
+	return responseVideo, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log"
+
+	"github.com/bootdotdev/learn-file-storage-s3-golang-starter/internal/database"
+	"github.com/google/uuid"
+)
+
+// signVideoForResponse returns a copy of video with every populated key
+// field (VideoURL, PeaksURL, ThumbnailURL, PreviewURL, MasterPlaylistURL)
+// replaced by a freshly signed, time-limited URL via cfg.videoURLSigner. The
+// DB only ever stores S3 keys in these fields — every response path that
+// serializes a video to JSON must sign them first or clients get an
+// unusable raw key.
+//
+// finalizeUploadedVideo (video_pipeline.go) does its own signing inline
+// because it has freshly-generated keys in hand that haven't made it back
+// into the DB-synced video struct yet (the HLS worker hasn't necessarily set
+// MasterPlaylistURL by the time the upload response goes out). This helper
+// is for the other case: a video whose key fields already reflect the DB
+// row, as any GetVideo-by-ID or list-videos read endpoint would have. This
+// tree only contains the upload handlers (handler_upload_video.go,
+// handler_video_uploads.go) — no read endpoint to wire this into — so
+// whatever handler elsewhere in the app serves previously-uploaded videos
+// needs to call this before responding.
+func signVideoForResponse(cfg *apiConfig, video database.Video) database.Video {
+	signed := video
+	signed.VideoURL = signKeyField(cfg, video.ID, "video", video.VideoURL)
+	signed.PeaksURL = signKeyField(cfg, video.ID, "peaks", video.PeaksURL)
+	signed.ThumbnailURL = signKeyField(cfg, video.ID, "poster", video.ThumbnailURL)
+	signed.PreviewURL = signKeyField(cfg, video.ID, "preview", video.PreviewURL)
+	signed.MasterPlaylistURL = signKeyField(cfg, video.ID, "master playlist", video.MasterPlaylistURL)
+	return signed
+}
+
+func signKeyField(cfg *apiConfig, videoID uuid.UUID, what string, key *string) *string {
+	if key == nil {
+		return nil
+	}
+	url, err := cfg.videoURLSigner.SignURL(*key)
+	if err != nil {
+		log.Printf("Couldn't sign %s URL for video %s: %v", what, videoID, err)
+		return nil
+	}
+	return &url
+}
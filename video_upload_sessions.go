@@ -0,0 +1,45 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// videoUploadSessionStore tracks in-flight chunked uploads (handler_video_uploads.go)
+// in memory, keyed by session ID. A server restart loses any in-progress
+// sessions; clients are expected to re-create one via POST /api/video_uploads.
+type videoUploadSessionStore struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]videoUploadSession
+}
+
+func newVideoUploadSessionStore() *videoUploadSessionStore {
+	return &videoUploadSessionStore{sessions: make(map[uuid.UUID]videoUploadSession)}
+}
+
+func (s *videoUploadSessionStore) save(session videoUploadSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = session
+	return nil
+}
+
+func (s *videoUploadSessionStore) get(id uuid.UUID) (videoUploadSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	return session, ok
+}
+
+func (s *videoUploadSessionStore) update(session videoUploadSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = session
+}
+
+func (s *videoUploadSessionStore) delete(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}